@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"bytes"
+	"fmt"
+
+	"filippo.io/age"
+
+	"sigs.k8s.io/kustomize/pkg/types"
+)
+
+func init() {
+	RegisterSecretProvider("age", func() SecretProvider { return ageProvider{} })
+}
+
+// ageProvider is the "age" SecretProvider: it encrypts each Secret data
+// value independently to every recipient in EncryptionArgs.Recipients,
+// using age's X25519 recipients. It's the reference implementation
+// proving out the SecretProvider plumbing; "sops" and "sealed-secrets"
+// are left for callers to register themselves.
+type ageProvider struct{}
+
+// Encrypt implements SecretProvider.
+func (ageProvider) Encrypt(
+	args *types.EncryptionArgs, data map[string][]byte) (
+	map[string][]byte, map[string]interface{}, error) {
+	if len(args.Recipients) == 0 {
+		return nil, nil, fmt.Errorf("age: Encryption.Recipients must not be empty")
+	}
+	recipients := make([]age.Recipient, 0, len(args.Recipients))
+	for _, r := range args.Recipients {
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("age: parsing recipient %q: %v", r, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	ciphertext := make(map[string][]byte, len(data))
+	for k, v := range data {
+		var buf bytes.Buffer
+		w, err := age.Encrypt(&buf, recipients...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("age: encrypting %s: %v", k, err)
+		}
+		if _, err := w.Write(v); err != nil {
+			return nil, nil, fmt.Errorf("age: encrypting %s: %v", k, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, nil, fmt.Errorf("age: encrypting %s: %v", k, err)
+		}
+		ciphertext[k] = buf.Bytes()
+	}
+	metadata := map[string]interface{}{
+		"age": map[string]interface{}{"recipients": args.Recipients},
+	}
+	return ciphertext, metadata, nil
+}
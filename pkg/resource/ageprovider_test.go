@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"filippo.io/age"
+
+	"sigs.k8s.io/kustomize/pkg/types"
+)
+
+// TestAgeProviderRoundTrip encrypts a Secret's data through ageProvider
+// and decrypts it back with the matching identity, proving out the
+// SecretProvider interface end to end.
+func TestAgeProviderRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generating identity: %v", err)
+	}
+
+	plaintext := map[string][]byte{
+		"username": []byte("admin"),
+		"password": []byte("s3cr3t"),
+	}
+	args := &types.EncryptionArgs{Recipients: []string{identity.Recipient().String()}}
+
+	ciphertext, metadata, err := (ageProvider{}).Encrypt(args, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if _, ok := metadata["age"]; !ok {
+		t.Errorf("metadata missing \"age\" stanza: %v", metadata)
+	}
+
+	for k, want := range plaintext {
+		ct, ok := ciphertext[k]
+		if !ok {
+			t.Fatalf("ciphertext missing key %q", k)
+		}
+		if bytes.Equal(ct, want) {
+			t.Fatalf("data[%s] was not encrypted at all", k)
+		}
+		r, err := age.Decrypt(bytes.NewReader(ct), identity)
+		if err != nil {
+			t.Fatalf("Decrypt(%s): %v", k, err)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("reading decrypted %s: %v", k, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("data[%s] round-trip mismatch: got %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestAgeProviderRequiresRecipients(t *testing.T) {
+	_, _, err := (ageProvider{}).Encrypt(&types.EncryptionArgs{}, map[string][]byte{"k": []byte("v")})
+	if err == nil {
+		t.Error("expected an error encrypting with no recipients")
+	}
+}
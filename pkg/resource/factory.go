@@ -17,19 +17,27 @@ limitations under the License.
 package resource
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"sort"
 	"strings"
 
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/kustomize/internal/kusterr"
 	"sigs.k8s.io/kustomize/pkg/ifc"
+	"sigs.k8s.io/kustomize/pkg/openapi"
 	"sigs.k8s.io/kustomize/pkg/types"
 )
 
 // Factory makes instances of Resource.
 type Factory struct {
-	kf ifc.KunstructuredFactory
+	kf          ifc.KunstructuredFactory
+	schema      openapi.Resources
+	trackOrigin bool
 }
 
 // NewFactory makes an instance of Factory.
@@ -37,10 +45,20 @@ func NewFactory(kf ifc.KunstructuredFactory) *Factory {
 	return &Factory{kf: kf}
 }
 
+// WithOriginTracking toggles whether Resources the Factory produces are
+// stamped with a config.kubernetes.io/origin annotation recording the
+// generator that produced them (see Origin). Off by default.
+func (rf *Factory) WithOriginTracking(track bool) *Factory {
+	rf.trackOrigin = track
+	return rf
+}
+
 // FromMap returns a new instance of Resource.
 func (rf *Factory) FromMap(m map[string]interface{}) *Resource {
+	u := rf.kf.FromMap(m)
+	rf.stampOrigin(u, &Origin{Generator: "FromMap"})
 	return &Resource{
-		Kunstructured: rf.kf.FromMap(m),
+		Kunstructured: u,
 		options:       types.NewGenArgs(nil, nil),
 	}
 }
@@ -59,6 +77,14 @@ func (rf *Factory) FromKunstructured(
 	if u == nil {
 		log.Fatal("unstruct ifc must not be null")
 	}
+	rf.stampOrigin(u, &Origin{Generator: "FromKunstructured"})
+	return rf.fromKunstructuredNoOrigin(u)
+}
+
+// fromKunstructuredNoOrigin builds a Resource around u without stamping
+// an origin annotation, for callers (e.g. SliceFromBytes) that attach
+// their own origin, or none.
+func (rf *Factory) fromKunstructuredNoOrigin(u ifc.Kunstructured) *Resource {
 	return &Resource{
 		Kunstructured: u,
 		options:       types.NewGenArgs(nil, nil),
@@ -66,11 +92,24 @@ func (rf *Factory) FromKunstructured(
 }
 
 // SliceFromPatches returns a slice of resources given a patch path
-// slice from a kustomization file.
+// slice from a kustomization file. A path of the form
+// oci://registry/repo:tag is pulled as a kustomize OCI artifact (see
+// pullOCIArtifact) instead of being resolved through ldr. Resolving
+// oci:// bases:/resources: entries is the loader's job, not this
+// function's; see NewLoaderIfOCIRef, which an ifc.Loader.New()
+// implementation should call to get the same support there.
 func (rf *Factory) SliceFromPatches(
 	ldr ifc.Loader, paths []types.PatchStrategicMerge) ([]*Resource, error) {
 	var result []*Resource
 	for _, path := range paths {
+		if ref, ok := parseOCIRef(string(path)); ok {
+			res, err := rf.sliceFromOCIArtifact(ref)
+			if err != nil {
+				return nil, kusterr.Handler(err, string(path))
+			}
+			result = append(result, res...)
+			continue
+		}
 		content, err := ldr.Load(string(path))
 		if err != nil {
 			return nil, err
@@ -84,6 +123,136 @@ func (rf *Factory) SliceFromPatches(
 	return result, nil
 }
 
+// sliceFromOCIArtifact pulls ref and decodes every manifest file it
+// contains, in a stable (lexical) order, via SliceFromStream so each
+// Resource carries SourceInfo naming its file within the artifact. The
+// artifact's files are already held in memory by the loader
+// pullOCIArtifact returns.
+func (rf *Factory) sliceFromOCIArtifact(ref *ociRef) ([]*Resource, error) {
+	ldr, err := pullOCIArtifact(ref)
+	if err != nil {
+		return nil, err
+	}
+	defer ldr.Cleanup()
+	ml, ok := ldr.(*memLoader)
+	if !ok {
+		return nil, fmt.Errorf("unexpected loader type %T for %s", ldr, ref.Repo)
+	}
+	names := make([]string, 0, len(ml.files))
+	for name := range ml.files {
+		if isManifestFile(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var result []*Resource
+	for _, name := range names {
+		content, err := ml.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		res, err := rf.SliceFromStream(
+			bytes.NewReader(content),
+			SourceInfo{Path: fmt.Sprintf("%s/%s", ml.Root(), name)})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res...)
+	}
+	return result, nil
+}
+
+func isManifestFile(name string) bool {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader wraps an io.Reader and tracks the number of bytes
+// consumed so far, so SliceFromStream can stamp each decoded document
+// with its byte offset without buffering the stream to compute it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// SliceFromStream unmarshalls a multi-document YAML/JSON stream into a
+// Resource slice, decoding one document at a time via a streaming
+// decoder rather than buffering the whole stream. This lets kustomize
+// handle very large multi-doc manifests (helm renders, big inventories)
+// without loading them all into memory. Each returned Resource is
+// stamped with SourceInfo recording src's path, the document's byte
+// offset within it, and the document's index, so downstream diff and
+// inventory tooling can point a user at the exact file and document that
+// produced a given object.
+func (rf *Factory) SliceFromStream(
+	r io.Reader, src SourceInfo) ([]*Resource, error) {
+	cr := &countingReader{r: r}
+	br := bufio.NewReader(cr)
+	reader := utilyaml.NewYAMLReader(br)
+	var result []*Resource
+	for index := 0; ; index++ {
+		// cr.n is how many bytes bufio has pulled from the underlying
+		// reader, which can run ahead of what reader.Read() has
+		// actually consumed by up to a full buffer fill. Subtracting
+		// what's still sitting in br's buffer gives the true position
+		// in the stream at the start of this document.
+		offset := cr.n - int64(br.Buffered())
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, kusterr.Handler(err, src.Path)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		res, err := rf.SliceFromBytes(doc)
+		if err != nil {
+			return nil, kusterr.Handler(
+				err, fmt.Sprintf("%s (doc %d)", src.Path, index))
+		}
+		docSrc := src
+		docSrc.Offset = offset
+		docSrc.Index = index
+		for _, item := range res {
+			item.source = &docSrc
+		}
+		result = append(result, res...)
+	}
+	return result, nil
+}
+
+// SliceFromReaders is the multi-source counterpart to SliceFromStream:
+// it decodes each reader in turn against its matching SourceInfo and
+// concatenates the results in order.
+func (rf *Factory) SliceFromReaders(
+	srcs []SourceInfo, readers []io.Reader) ([]*Resource, error) {
+	if len(srcs) != len(readers) {
+		return nil, fmt.Errorf(
+			"got %d sources for %d readers", len(srcs), len(readers))
+	}
+	var result []*Resource
+	for i, r := range readers {
+		res, err := rf.SliceFromStream(r, srcs[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res...)
+	}
+	return result, nil
+}
+
 // FromBytes unmarshalls bytes into one Resource.
 func (rf *Factory) FromBytes(in []byte) (*Resource, error) {
 	result, err := rf.SliceFromBytes(in)
@@ -97,16 +266,25 @@ func (rf *Factory) FromBytes(in []byte) (*Resource, error) {
 	return result[0], nil
 }
 
-// SliceFromBytes unmarshalls bytes into a Resource slice.
+// SliceFromBytes unmarshalls bytes into a Resource slice. An item
+// unwrapped from a top-level List is stamped with an Origin recording
+// the List's identity and the item's index within it, so it can still
+// be attributed back to its List after the wrapper is discarded.
 func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
 	kunStructs, err := rf.kf.SliceFromBytes(in)
 	if err != nil {
 		return nil, err
 	}
+	// origins tracks, in parallel with kunStructs, the parent List each
+	// entry was unwrapped from (nil for a document that wasn't inside a
+	// List).
+	origins := make([]*Origin, len(kunStructs))
 	var result []*Resource
 	for len(kunStructs) > 0 {
 		u := kunStructs[0]
+		o := origins[0]
 		kunStructs = kunStructs[1:]
+		origins = origins[1:]
 		if strings.HasSuffix(u.GetKind(), "List") {
 			items := u.Map()["items"]
 			itemsSlice, ok := items.([]interface{})
@@ -117,7 +295,7 @@ func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
 				}
 				return nil, fmt.Errorf("items in List is type %T, expected array", items)
 			}
-			for _, item := range itemsSlice {
+			for i, item := range itemsSlice {
 				itemJSON, err := json.Marshal(item)
 				if err != nil {
 					return nil, err
@@ -126,11 +304,31 @@ func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
 				if err != nil {
 					return nil, err
 				}
+				itemIndex := i
+				itemOrigin := &Origin{
+					Generator: "List",
+					ListGVK:   u.GetKind(),
+					ListName:  u.GetName(),
+					ItemIndex: &itemIndex,
+				}
 				// append innerU to kunStructs so nested Lists can be handled
 				kunStructs = append(kunStructs, innerU...)
+				for range innerU {
+					origins = append(origins, itemOrigin)
+				}
 			}
 		} else {
-			result = append(result, rf.FromKunstructured(u))
+			if o != nil {
+				rf.stampOrigin(u, o)
+			}
+			result = append(result, rf.fromKunstructuredNoOrigin(u))
+		}
+	}
+	if rf.schema != nil {
+		for _, r := range result {
+			if err := rf.validateAgainstSchema(r); err != nil {
+				return nil, kusterr.Handler(err, r.GetName())
+			}
 		}
 	}
 	return result, nil
@@ -145,6 +343,7 @@ func (rf *Factory) MakeConfigMap(
 	if err != nil {
 		return nil, err
 	}
+	rf.stampOrigin(u, &Origin{Generator: "ConfigMap", Path: ldr.Root()})
 	return &Resource{
 		Kunstructured: u,
 		options: types.NewGenArgs(
@@ -153,15 +352,30 @@ func (rf *Factory) MakeConfigMap(
 	}, nil
 }
 
-// MakeSecret makes an instance of Resource for Secret
+// MakeSecret makes an instance of Resource for Secret. If
+// args.Encryption names a SecretProvider other than "sealed-secrets",
+// the Secret's data is encrypted in place through that provider before
+// it's wrapped; use MakeSealedSecret for the "sealed-secrets" provider,
+// which emits a different kind rather than an in-place-encrypted
+// Secret.
 func (rf *Factory) MakeSecret(
 	ldr ifc.Loader,
 	options *types.GeneratorOptions,
 	args *types.SecretArgs) (*Resource, error) {
+	if args.Encryption != nil && args.Encryption.Provider == "sealed-secrets" {
+		return nil, fmt.Errorf(
+			`MakeSecret does not support Encryption.Provider == "sealed-secrets"; use MakeSealedSecret, which emits a SealedSecret instead of a Secret`)
+	}
 	u, err := rf.kf.MakeSecret(ldr, options, args)
 	if err != nil {
 		return nil, err
 	}
+	if args.Encryption != nil {
+		if err := encryptSecret(u, args.Encryption); err != nil {
+			return nil, err
+		}
+	}
+	rf.stampOrigin(u, &Origin{Generator: "Secret", Path: ldr.Root()})
 	return &Resource{
 		Kunstructured: u,
 		options: types.NewGenArgs(
@@ -169,3 +383,34 @@ func (rf *Factory) MakeSecret(
 			options),
 	}, nil
 }
+
+// MakeSealedSecret makes an instance of Resource holding a
+// bitnami.com/v1alpha1 SealedSecret, produced by running the would-be
+// Secret's data through the "sealed-secrets" SecretProvider named in
+// args.Encryption. kustomize doesn't bundle a "sealed-secrets"
+// SecretProvider; a caller must RegisterSecretProvider one (the way
+// ageprovider.go does for "age") before this succeeds.
+func (rf *Factory) MakeSealedSecret(
+	ldr ifc.Loader,
+	options *types.GeneratorOptions,
+	args *types.SecretArgs) (*Resource, error) {
+	if args.Encryption == nil || args.Encryption.Provider != "sealed-secrets" {
+		return nil, fmt.Errorf(
+			`MakeSealedSecret requires Encryption.Provider == "sealed-secrets"`)
+	}
+	u, err := rf.kf.MakeSecret(ldr, options, args)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealSecret(rf.kf, u, args.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	rf.stampOrigin(sealed, &Origin{Generator: "SealedSecret", Path: ldr.Root()})
+	return &Resource{
+		Kunstructured: sealed,
+		options: types.NewGenArgs(
+			&types.GeneratorArgs{Behavior: args.Behavior},
+			options),
+	}, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/k8sdeps/kunstruct"
+	"sigs.k8s.io/kustomize/pkg/resource"
+	"sigs.k8s.io/kustomize/pkg/types"
+)
+
+// TestSliceFromStreamOffsets exercises a stream whose first document is
+// larger than one bufio.Reader buffer fill, so a naive offset computed
+// from bytes read off the underlying reader (rather than bytes actually
+// consumed by the YAML document reader) would report every later
+// document at the same, too-large offset.
+func TestSliceFromStreamOffsets(t *testing.T) {
+	pad := strings.Repeat("x", 8192)
+	doc0 := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm0\ndata:\n  pad: " + pad + "\n"
+	doc1 := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"
+	stream := doc0 + "---\n" + doc1
+
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	res, err := rf.SliceFromStream(
+		strings.NewReader(stream), resource.SourceInfo{Path: "stream.yaml"})
+	if err != nil {
+		t.Fatalf("SliceFromStream: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("got %d resources, want 2", len(res))
+	}
+
+	src0 := res[0].Source()
+	src1 := res[1].Source()
+	if src0 == nil || src1 == nil {
+		t.Fatalf("expected both resources to carry SourceInfo, got %v and %v", src0, src1)
+	}
+	if src0.Index != 0 || src1.Index != 1 {
+		t.Errorf("got doc indices %d, %d, want 0, 1", src0.Index, src1.Index)
+	}
+	if src0.Offset != 0 {
+		t.Errorf("doc 0 offset = %d, want 0", src0.Offset)
+	}
+	wantOffset1 := int64(len(doc0) + len("---\n"))
+	if src1.Offset != wantOffset1 {
+		t.Errorf("doc 1 offset = %d, want %d", src1.Offset, wantOffset1)
+	}
+}
+
+// TestMakeSecretRejectsSealedSecretsProvider verifies that MakeSecret
+// refuses an Encryption.Provider of "sealed-secrets" rather than
+// running it through encryptSecret and emitting a malformed plain
+// Secret; that provider's output is only valid wrapped as the
+// SealedSecret kind MakeSealedSecret returns.
+func TestMakeSecretRejectsSealedSecretsProvider(t *testing.T) {
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	args := &types.SecretArgs{Encryption: &types.EncryptionArgs{Provider: "sealed-secrets"}}
+	_, err := rf.MakeSecret(nil, nil, args)
+	if err == nil {
+		t.Fatal(`MakeSecret with Encryption.Provider == "sealed-secrets" returned no error, want one`)
+	}
+}
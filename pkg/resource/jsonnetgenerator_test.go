@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"fmt"
+	"testing"
+
+	"sigs.k8s.io/kustomize/pkg/ifc"
+)
+
+// fakeLoader is a minimal ifc.Loader backed by an in-memory path to
+// content map, used to test loaderImporter without touching disk.
+type fakeLoader struct {
+	root  string
+	files map[string]string
+}
+
+func (f *fakeLoader) Root() string { return f.root }
+func (f *fakeLoader) New(newRoot string) (ifc.Loader, error) {
+	return &fakeLoader{root: newRoot, files: f.files}, nil
+}
+func (f *fakeLoader) Load(location string) ([]byte, error) {
+	content, ok := f.files[location]
+	if !ok {
+		return nil, fmt.Errorf("%s: no such file", location)
+	}
+	return []byte(content), nil
+}
+func (f *fakeLoader) Cleanup() error { return nil }
+
+// TestLoaderImporterResolvesRelativeToImportedFrom verifies that an
+// import issued from a nested file resolves relative to that file's
+// own directory before falling back to the loader root or JPath, the
+// way standard Jsonnet import resolution works.
+func TestLoaderImporterResolvesRelativeToImportedFrom(t *testing.T) {
+	ldr := &fakeLoader{
+		root: ".",
+		files: map[string]string{
+			"lib/util.libsonnet": "{ greeting: 'hi' }",
+		},
+	}
+	li := &loaderImporter{ldr: ldr}
+
+	contents, foundAt, err := li.Import("lib/main.jsonnet", "util.libsonnet")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if foundAt != "lib/util.libsonnet" {
+		t.Errorf("foundAt = %q, want %q", foundAt, "lib/util.libsonnet")
+	}
+	if contents.String() != "{ greeting: 'hi' }" {
+		t.Errorf("contents = %q", contents.String())
+	}
+}
+
+// TestAllowedNativeFuncsRejectNonStringArgs verifies that the allowlisted
+// native functions return a Jsonnet-visible error on a non-string
+// argument instead of panicking the whole evaluation - they're exposed
+// to arbitrary user Jsonnet, so that boundary must fail gracefully.
+func TestAllowedNativeFuncsRejectNonStringArgs(t *testing.T) {
+	funcs := make(map[string]func(args []interface{}) (interface{}, error))
+	for _, nf := range allowedNativeFuncs() {
+		funcs[nf.Name] = nf.Func
+	}
+
+	cases := []struct {
+		name string
+		args []interface{}
+	}{
+		{"parseYaml", []interface{}{123}},
+		{"parseJson", []interface{}{123}},
+		{"regexMatch", []interface{}{123, "x"}},
+		{"regexMatch", []interface{}{"x", 123}},
+	}
+	for _, c := range cases {
+		_, err := funcs[c.name](c.args)
+		if err == nil {
+			t.Errorf("%s(%v): got nil error, want a type error", c.name, c.args)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kustomize/pkg/ifc"
+)
+
+// memLoader is an ifc.Loader backed entirely by an in-memory path to
+// content map, used to expose an unpacked OCI artifact without writing
+// its contents back out to disk.
+type memLoader struct {
+	root  string
+	files map[string][]byte
+}
+
+func newMemLoader(root string, files map[string][]byte) *memLoader {
+	return &memLoader{root: root, files: files}
+}
+
+// Root returns the identifier the loader was constructed with, e.g. the
+// oci:// reference it was unpacked from.
+func (m *memLoader) Root() string {
+	return m.root
+}
+
+// New returns a loader for a path relative to this one. Since an OCI
+// artifact is self-contained, newRoot must stay within it.
+func (m *memLoader) New(newRoot string) (ifc.Loader, error) {
+	prefix := strings.TrimPrefix(path.Clean(newRoot), "/") + "/"
+	scoped := make(map[string][]byte)
+	for p, content := range m.files {
+		if rest := strings.TrimPrefix(p, prefix); rest != p {
+			scoped[rest] = content
+		}
+	}
+	if len(scoped) == 0 {
+		return nil, fmt.Errorf("%s: no such path in %s", newRoot, m.root)
+	}
+	return newMemLoader(path.Join(m.root, newRoot), scoped), nil
+}
+
+// Load returns the contents of location, relative to this loader's
+// root.
+func (m *memLoader) Load(location string) ([]byte, error) {
+	content, ok := m.files[strings.TrimPrefix(path.Clean(location), "/")]
+	if !ok {
+		return nil, fmt.Errorf("%s: no such file in %s", location, m.root)
+	}
+	return content, nil
+}
+
+// Cleanup is a no-op; memLoader holds no on-disk state of its own.
+func (m *memLoader) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/reg/registry"
+
+	"sigs.k8s.io/kustomize/pkg/ifc"
+)
+
+// kustomizeOCIMediaType is the media type Flux-style kustomize OCI
+// artifacts use for their single content layer.
+const kustomizeOCIMediaType = "application/vnd.cncf.kustomize.content.v1.tar+gzip"
+
+// maxOCILayerBytes caps how much compressed layer data pullOCIArtifact
+// will read from a registry response, and maxOCIDecompressedBytes caps
+// how much it will inflate from that layer, so a malicious or
+// misconfigured registry can't exhaust memory with an oversized or
+// zip-bomb response.
+const (
+	maxOCILayerBytes        = 100 << 20 // 100MiB compressed
+	maxOCIDecompressedBytes = 500 << 20 // 500MiB inflated
+)
+
+// ociTagCacheTTL bounds how long a pull of an unpinned (floating-tag)
+// oci:// reference is served from the local cache before being
+// re-fetched. A digest-pinned reference has no such limit: its content
+// can't change without changing the pin, so it's cached indefinitely.
+const ociTagCacheTTL = 5 * time.Minute
+
+// ociRefPattern matches oci://registry/repo[:tag][@sha256:digest], the
+// form SliceFromPatches resolves directly and NewLoaderIfOCIRef resolves
+// on behalf of base references (see NewLoaderIfOCIRef).
+var ociRefPattern = regexp.MustCompile(
+	`^oci://([^/]+)/([^:@]+)(?::([^@]+))?(?:@(sha256:[0-9a-f]{64}))?$`)
+
+// ociRef is a parsed oci:// reference.
+type ociRef struct {
+	Registry string
+	Repo     string
+	Tag      string
+	Digest   string
+}
+
+// parseOCIRef reports whether path is an oci:// reference, returning
+// its parsed form when it is.
+func parseOCIRef(path string) (*ociRef, bool) {
+	m := ociRefPattern.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+	tag := m[3]
+	if tag == "" {
+		tag = "latest"
+	}
+	return &ociRef{Registry: m[1], Repo: m[2], Tag: tag, Digest: m[4]}, true
+}
+
+// ociCacheDir returns the directory OCI pulls are cached under, keyed
+// by artifact digest so that repeated references to the same pinned
+// artifact skip the registry round-trip.
+func ociCacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, "kustomize", "oci"), nil
+}
+
+// ociCachePath returns the on-disk cache path for ref: one keyed by
+// digest for a pinned reference, one keyed by registry/repo/tag for a
+// floating one.
+func ociCachePath(cacheRoot string, ref *ociRef) string {
+	if ref.Digest != "" {
+		return filepath.Join(cacheRoot, strings.ReplaceAll(ref.Digest, ":", "_"))
+	}
+	return filepath.Join(cacheRoot, ref.Registry, ref.Repo, ref.Tag)
+}
+
+// readOCICache returns the cached layer bytes for ref, if present and,
+// for an unpinned ref, still within ociTagCacheTTL.
+func readOCICache(cachePath string, ref *ociRef) ([]byte, bool) {
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	if ref.Digest == "" && time.Since(info.ModTime()) > ociTagCacheTTL {
+		return nil, false
+	}
+	blob, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	return blob, true
+}
+
+// verifyOCIDigest reports an error if ref pins a digest and it doesn't
+// match the sha256 of blob. It never trusts a digest claimed by the
+// registry's own manifest response - only one computed from the bytes
+// actually downloaded.
+func verifyOCIDigest(ref *ociRef, blob []byte) error {
+	if ref.Digest == "" {
+		return nil
+	}
+	sum := sha256.Sum256(blob)
+	got := "sha256:" + hex.EncodeToString(sum[:])
+	if got != ref.Digest {
+		return fmt.Errorf(
+			"digest mismatch for %s/%s:%s: got %s, want %s",
+			ref.Registry, ref.Repo, ref.Tag, got, ref.Digest)
+	}
+	return nil
+}
+
+// NewLoaderIfOCIRef reports whether path is an oci:// reference and, if
+// so, pulls it and returns an ifc.Loader rooted on its contents. It's
+// the hook a kustomization's own ifc.Loader implementation should call
+// from New() before falling back to its normal file/git/http resolution,
+// so that bases:/resources: entries (not just SliceFromPatches, which
+// calls pullOCIArtifact directly) can also name an oci:// artifact.
+func NewLoaderIfOCIRef(path string) (ifc.Loader, bool, error) {
+	ref, ok := parseOCIRef(path)
+	if !ok {
+		return nil, false, nil
+	}
+	ldr, err := pullOCIArtifact(ref)
+	if err != nil {
+		return nil, true, err
+	}
+	return ldr, true, nil
+}
+
+// pullOCIArtifact fetches the single content layer of the OCI artifact
+// identified by ref, verifies its digest against ref.Digest when
+// pinned, and returns it as an in-memory ifc.Loader. Layer bytes are
+// also persisted under ociCacheDir, keyed by digest (or by tag, subject
+// to ociTagCacheTTL, when unpinned), so a later pull of the same
+// artifact can read from disk instead of the registry.
+func pullOCIArtifact(ref *ociRef) (ifc.Loader, error) {
+	cacheRoot, err := ociCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	cachePath := ociCachePath(cacheRoot, ref)
+	root := fmt.Sprintf("oci://%s/%s:%s", ref.Registry, ref.Repo, ref.Tag)
+
+	if blob, ok := readOCICache(cachePath, ref); ok {
+		files, err := untarGzip(blob)
+		if err != nil {
+			return nil, err
+		}
+		return newMemLoader(root, files), nil
+	}
+
+	r, err := registry.New(registry.Opt{Domain: ref.Registry, SkipPing: true})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %v", ref.Registry, err)
+	}
+	manifest, err := r.ManifestV2(ref.Repo, ref.Tag)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"fetching manifest for %s/%s:%s: %v", ref.Registry, ref.Repo, ref.Tag, err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != kustomizeOCIMediaType {
+		return nil, fmt.Errorf(
+			"%s/%s:%s is not a kustomize OCI artifact (want one %s layer)",
+			ref.Registry, ref.Repo, ref.Tag, kustomizeOCIMediaType)
+	}
+	layer := manifest.Layers[0]
+
+	rc, err := r.DownloadLayer(ref.Repo, layer.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("downloading layer %s: %v", layer.Digest, err)
+	}
+	defer rc.Close()
+	blob, err := ioutil.ReadAll(io.LimitReader(rc, maxOCILayerBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) > maxOCILayerBytes {
+		return nil, fmt.Errorf(
+			"layer %s for %s/%s:%s exceeds %d byte limit",
+			layer.Digest, ref.Registry, ref.Repo, ref.Tag, maxOCILayerBytes)
+	}
+	// Trust only the digest computed from the bytes we actually
+	// received, never the manifest's own claim about the layer it
+	// is serving alongside.
+	if err := verifyOCIDigest(ref, blob); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(cachePath, blob, 0o644); err != nil {
+		return nil, err
+	}
+
+	files, err := untarGzip(blob)
+	if err != nil {
+		return nil, err
+	}
+	return newMemLoader(root, files), nil
+}
+
+// untarGzip decodes a gzip-compressed tar archive into a path->contents
+// map, entirely in memory, refusing to inflate more than
+// maxOCIDecompressedBytes total.
+func untarGzip(blob []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	lr := &io.LimitedReader{R: gz, N: maxOCIDecompressedBytes}
+	tr := tar.NewReader(lr)
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		if lr.N <= 0 {
+			return nil, fmt.Errorf(
+				"artifact inflates past %d byte limit", maxOCIDecompressedBytes)
+		}
+		files[hdr.Name] = content
+	}
+}
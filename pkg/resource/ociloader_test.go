@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseOCIRef(t *testing.T) {
+	cases := []struct {
+		path    string
+		wantOK  bool
+		wantRef ociRef
+	}{
+		{
+			path:   "oci://ghcr.io/example/bases:v1",
+			wantOK: true,
+			wantRef: ociRef{
+				Registry: "ghcr.io", Repo: "example/bases", Tag: "v1",
+			},
+		},
+		{
+			path:   "oci://ghcr.io/example/bases",
+			wantOK: true,
+			wantRef: ociRef{
+				Registry: "ghcr.io", Repo: "example/bases", Tag: "latest",
+			},
+		},
+		{
+			path:   "oci://ghcr.io/example/bases@sha256:" + sampleHex,
+			wantOK: true,
+			wantRef: ociRef{
+				Registry: "ghcr.io", Repo: "example/bases", Tag: "latest",
+				Digest: "sha256:" + sampleHex,
+			},
+		},
+		{path: "./bases/patch.yaml", wantOK: false},
+		{path: "https://example.com/patch.yaml", wantOK: false},
+	}
+	for _, c := range cases {
+		ref, ok := parseOCIRef(c.path)
+		if ok != c.wantOK {
+			t.Errorf("parseOCIRef(%q) ok = %v, want %v", c.path, ok, c.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if *ref != c.wantRef {
+			t.Errorf("parseOCIRef(%q) = %+v, want %+v", c.path, *ref, c.wantRef)
+		}
+	}
+}
+
+const sampleHex = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestNewLoaderIfOCIRefPassesThroughNonOCIPaths(t *testing.T) {
+	ldr, ok, err := NewLoaderIfOCIRef("./bases/overlay.yaml")
+	if ok || ldr != nil || err != nil {
+		t.Errorf("NewLoaderIfOCIRef(non-oci path) = (%v, %v, %v), want (nil, false, nil)", ldr, ok, err)
+	}
+}
+
+func TestVerifyOCIDigestRejectsMismatch(t *testing.T) {
+	blob := []byte("not the artifact you're looking for")
+	sum := sha256.Sum256(blob)
+	realDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	if err := verifyOCIDigest(&ociRef{Digest: realDigest}, blob); err != nil {
+		t.Errorf("verifyOCIDigest with matching digest returned error: %v", err)
+	}
+
+	tampered := append(append([]byte{}, blob...), 'x')
+	if err := verifyOCIDigest(&ociRef{Digest: realDigest}, tampered); err == nil {
+		t.Error("verifyOCIDigest accepted tampered bytes against the original digest")
+	}
+
+	if err := verifyOCIDigest(&ociRef{}, tampered); err != nil {
+		t.Errorf("verifyOCIDigest with no pin should be a no-op, got: %v", err)
+	}
+}
+
+func TestUntarGzipRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("apiVersion: v1\nkind: ConfigMap\n")
+	if err := tw.WriteHeader(&tar.Header{
+		Name: "base/configmap.yaml", Mode: 0o644, Size: int64(len(content)),
+	}); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip Close: %v", err)
+	}
+
+	files, err := untarGzip(buf.Bytes())
+	if err != nil {
+		t.Fatalf("untarGzip: %v", err)
+	}
+	got, ok := files["base/configmap.yaml"]
+	if !ok {
+		t.Fatalf("untarGzip dropped base/configmap.yaml, got keys %v", files)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("untarGzip content = %q, want %q", got, content)
+	}
+}
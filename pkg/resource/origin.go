@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/kustomize/pkg/ifc"
+)
+
+// originAnnotation is the well-known annotation server-side-apply-aware
+// inventory/prune tooling reads to attribute a live object back to the
+// generator that produced it.
+const originAnnotation = "config.kubernetes.io/origin"
+
+// Origin records where a generated Resource came from: which generator
+// produced it, the path of the kustomization that invoked that
+// generator, and - for an item unwrapped from a List in
+// Factory.SliceFromBytes - the identity of that List and the item's
+// index within it.
+type Origin struct {
+	// Generator names the kind of generator that produced the
+	// Resource, e.g. "ConfigMap", "Secret", or "List".
+	Generator string `json:"generator,omitempty"`
+
+	// Path is the kustomization that invoked the generator.
+	Path string `json:"path,omitempty"`
+
+	// ListGVK and ListName identify the parent List a Resource was
+	// unwrapped from, if any.
+	ListGVK  string `json:"listGvk,omitempty"`
+	ListName string `json:"listName,omitempty"`
+
+	// ItemIndex is the Resource's position among its parent List's
+	// items, if any.
+	ItemIndex *int `json:"itemIndex,omitempty"`
+}
+
+// Origin returns provenance for the Resource, or nil if none was
+// recorded (origin tracking is off by default; see
+// Factory.WithOriginTracking).
+func (r *Resource) Origin() *Origin {
+	raw, ok := r.GetAnnotations()[originAnnotation]
+	if !ok {
+		return nil
+	}
+	var o Origin
+	if err := json.Unmarshal([]byte(raw), &o); err != nil {
+		return nil
+	}
+	return &o
+}
+
+// stampOrigin annotates u with o, JSON-encoded, if origin tracking is
+// enabled on rf.
+func (rf *Factory) stampOrigin(u ifc.Kunstructured, o *Origin) {
+	if !rf.trackOrigin {
+		return
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return
+	}
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[originAnnotation] = string(data)
+	u.SetAnnotations(annotations)
+}
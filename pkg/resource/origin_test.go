@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/k8sdeps/kunstruct"
+	"sigs.k8s.io/kustomize/pkg/resource"
+)
+
+func TestFromMapOriginTracking(t *testing.T) {
+	m := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm"},
+	}
+
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	if origin := rf.FromMap(m).Origin(); origin != nil {
+		t.Errorf("Origin() without WithOriginTracking = %+v, want nil", origin)
+	}
+
+	rf.WithOriginTracking(true)
+	res := rf.FromMap(m)
+	origin := res.Origin()
+	if origin == nil {
+		t.Fatal("Origin() with WithOriginTracking(true) = nil, want non-nil")
+	}
+	if origin.Generator != "FromMap" {
+		t.Errorf("origin.Generator = %q, want %q", origin.Generator, "FromMap")
+	}
+}
+
+func TestSliceFromBytesListItemOrigin(t *testing.T) {
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	rf.WithOriginTracking(true)
+
+	list := "apiVersion: v1\nkind: ConfigMapList\nmetadata:\n  name: mylist\nitems:\n" +
+		"- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: cm0\n" +
+		"- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: cm1\n"
+	res, err := rf.SliceFromBytes([]byte(list))
+	if err != nil {
+		t.Fatalf("SliceFromBytes: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("got %d resources, want 2", len(res))
+	}
+	for i, r := range res {
+		origin := r.Origin()
+		if origin == nil {
+			t.Fatalf("item %d: Origin() = nil, want non-nil", i)
+		}
+		if origin.ListName != "mylist" || origin.ItemIndex == nil || *origin.ItemIndex != i {
+			t.Errorf("item %d: origin = %+v, want ListName=mylist ItemIndex=%d", i, origin, i)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"sigs.k8s.io/kustomize/pkg/ifc"
+	"sigs.k8s.io/kustomize/pkg/types"
+)
+
+// Resource wraps a Kunstructured object along with the generator options
+// that produced it.
+type Resource struct {
+	ifc.Kunstructured
+	options *types.GenArgs
+	source  *SourceInfo
+}
+
+// Source returns provenance for the Resource, or nil if it wasn't
+// produced via Factory.SliceFromStream/SliceFromReaders.
+func (r *Resource) Source() *SourceInfo {
+	return r.source
+}
+
+// Behavior returns the resource's generation behavior.
+func (r *Resource) Behavior() types.GenerationBehavior {
+	return r.options.Behavior()
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"sigs.k8s.io/kustomize/pkg/ifc"
+	"sigs.k8s.io/kustomize/pkg/types"
+)
+
+// SecretProvider encrypts the data values of a generated Secret for a
+// specific backend (e.g. sops, sealed-secrets, age). Implementations
+// are registered with RegisterSecretProvider so downstream code can
+// plug in KMS/Vault/age backends without patching kustomize itself.
+//
+// Only "age" (ageProvider, in ageprovider.go) ships built in, with a
+// round-trip test exercising it end to end. "sops" and "sealed-secrets"
+// are not implemented in this package: they are names a caller is
+// expected to register their own SecretProvider under, and until one
+// is, MakeSecret (for "sops") and MakeSealedSecret (for
+// "sealed-secrets") both fail with "no SecretProvider registered under
+// ...". In particular MakeSealedSecret cannot produce a SealedSecret
+// out of the box; bundling a real "sealed-secrets" provider is out of
+// scope here and left as follow-up work.
+type SecretProvider interface {
+	// Encrypt replaces every value in data with its ciphertext for the
+	// recipients/key named in args, and returns any provider metadata
+	// (e.g. a sops stanza) that should be merged into the emitted
+	// object alongside it.
+	Encrypt(args *types.EncryptionArgs, data map[string][]byte) (
+		ciphertext map[string][]byte, metadata map[string]interface{}, err error)
+}
+
+// SecretProviderFactory constructs a SecretProvider on demand, so
+// providers that hold per-use state (e.g. a KMS client) don't have to
+// be shared across calls.
+type SecretProviderFactory func() SecretProvider
+
+var secretProviders = map[string]SecretProviderFactory{}
+
+// RegisterSecretProvider registers factory under name for use as
+// types.EncryptionArgs.Provider. Registering under a name that's
+// already taken replaces the previous factory.
+func RegisterSecretProvider(name string, factory SecretProviderFactory) {
+	secretProviders[name] = factory
+}
+
+func lookupSecretProvider(name string) (SecretProvider, error) {
+	factory, ok := secretProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("no SecretProvider registered under %q", name)
+	}
+	return factory(), nil
+}
+
+// secretData reads the base64-encoded "data" field of a generated
+// Secret back into raw bytes.
+func secretData(u ifc.Kunstructured) (map[string][]byte, error) {
+	raw, _ := u.Map()["data"].(map[string]interface{})
+	data := make(map[string][]byte, len(raw))
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("data[%s] is type %T, expected string", k, v)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("data[%s]: %v", k, err)
+		}
+		data[k] = decoded
+	}
+	return data, nil
+}
+
+// encryptSecret runs a generated Secret's data through the provider
+// named in enc, in place, and - for providers other than
+// "sealed-secrets" - merges the provider's metadata (e.g. a sops
+// stanza) onto the object so it stays a plain Secret that decrypts on
+// read rather than changing kind.
+func encryptSecret(u ifc.Kunstructured, enc *types.EncryptionArgs) error {
+	provider, err := lookupSecretProvider(enc.Provider)
+	if err != nil {
+		return err
+	}
+	data, err := secretData(u)
+	if err != nil {
+		return err
+	}
+	ciphertext, metadata, err := provider.Encrypt(enc, data)
+	if err != nil {
+		return fmt.Errorf("encrypting secret with provider %q: %v", enc.Provider, err)
+	}
+	m := u.Map()
+	encoded := make(map[string]interface{}, len(ciphertext))
+	for k, v := range ciphertext {
+		encoded[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	m["data"] = encoded
+	for k, v := range metadata {
+		m[k] = v
+	}
+	return u.SetMap(m)
+}
+
+// sealSecret runs a generated Secret's data through the "sealed-secrets"
+// provider and rewraps the result as a bitnami.com/v1alpha1
+// SealedSecret via kf, rather than mutating the Secret in place.
+func sealSecret(
+	kf ifc.KunstructuredFactory, u ifc.Kunstructured,
+	enc *types.EncryptionArgs) (ifc.Kunstructured, error) {
+	provider, err := lookupSecretProvider(enc.Provider)
+	if err != nil {
+		return nil, err
+	}
+	data, err := secretData(u)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, _, err := provider.Encrypt(enc, data)
+	if err != nil {
+		return nil, fmt.Errorf("sealing secret with provider %q: %v", enc.Provider, err)
+	}
+	encryptedData := make(map[string]interface{}, len(ciphertext))
+	for k, v := range ciphertext {
+		encryptedData[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	m := u.Map()
+	metadata, _ := m["metadata"].(map[string]interface{})
+	sealed := map[string]interface{}{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"encryptedData": encryptedData,
+			"template": map[string]interface{}{
+				"metadata": metadata,
+				"type":     m["type"],
+			},
+		},
+	}
+	return kf.FromMap(sealed), nil
+}
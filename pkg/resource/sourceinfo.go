@@ -0,0 +1,34 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+// SourceInfo records where a Resource came from when it was decoded by
+// Factory.SliceFromStream or Factory.SliceFromReaders: the path it was
+// read from, the byte offset at which its YAML document begins within
+// that path, and the document's zero-based position among the other
+// documents read from the same source. Downstream tooling (diffing,
+// inventory/prune) can use it to point a user at the exact file and
+// document that produced a given object.
+type SourceInfo struct {
+	// Path is the file or stream identifier the document was read from.
+	Path string
+	// Offset is the byte offset of the document's start within Path.
+	Offset int64
+	// Index is the document's position among sibling documents read
+	// from the same Path.
+	Index int
+}
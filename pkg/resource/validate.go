@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/util/proto/validation"
+
+	"sigs.k8s.io/kustomize/pkg/openapi"
+)
+
+// WithSchema opts the Factory into OpenAPI validation: every Resource
+// produced by SliceFromBytes has its GVK looked up in resources and is
+// rejected if it has unknown fields, wrong-typed fields, or is missing
+// a required field. resources may merge a live cluster's schema, a
+// pinned swagger.json, and an embedded fallback for core kinds; CRD
+// schemas supplied by the user coexist with built-ins there. Passing a
+// nil Resources (the default) leaves validation off.
+func (rf *Factory) WithSchema(resources openapi.Resources) *Factory {
+	rf.schema = resources
+	return rf
+}
+
+// validateAgainstSchema validates r against rf.schema, aggregating all
+// field errors for r into a single error with JSON-pointer paths. It is
+// a no-op if no schema was set via WithSchema, or if rf.schema has no
+// entry for r's GVK.
+func (rf *Factory) validateAgainstSchema(r *Resource) error {
+	if rf.schema == nil {
+		return nil
+	}
+	schema := rf.schema.LookupResource(r.GetGvk())
+	if schema == nil {
+		return nil
+	}
+	data, err := json.Marshal(r.Map())
+	if err != nil {
+		return err
+	}
+	verrs := validation.NewSchemaValidation(rf.schema).ValidateBytes(data)
+	if len(verrs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(verrs))
+	for i, verr := range verrs {
+		msgs[i] = verr.Error()
+	}
+	return fmt.Errorf(
+		"%s %s failed openapi validation:\n%s",
+		r.GetGvk(), r.GetName(), strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/k8sdeps/kunstruct"
+	"sigs.k8s.io/kustomize/pkg/resource"
+)
+
+// TestSliceFromBytesSkipsValidationByDefault ensures OpenAPI validation
+// stays opt-in: a Factory that never calls WithSchema must not reject
+// any document, however malformed a real schema might consider it.
+func TestSliceFromBytesSkipsValidationByDefault(t *testing.T) {
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	res, err := rf.SliceFromBytes([]byte(
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\nthisFieldDoesNotExist: true\n"))
+	if err != nil {
+		t.Fatalf("SliceFromBytes with no schema set returned an error: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("got %d resources, want 1", len(res))
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// EncryptionArgs names the SecretProvider a SecretArgs should encrypt
+// its data through, and the arguments that provider needs.
+type EncryptionArgs struct {
+	// Provider is the name a SecretProvider was registered under, e.g.
+	// "sops", "sealed-secrets", or "age".
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+
+	// Recipients are the provider-specific keys (age public keys, PGP
+	// fingerprints, KMS key ARNs, ...) data should be encrypted for.
+	Recipients []string `json:"recipients,omitempty" yaml:"recipients,omitempty"`
+
+	// KeyRef identifies a single key to use instead of, or in addition
+	// to, Recipients, e.g. a Vault path or KMS key id.
+	KeyRef string `json:"keyRef,omitempty" yaml:"keyRef,omitempty"`
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// JsonnetArgs contains the options for generating Resources by
+// evaluating a Jsonnet entry file.
+type JsonnetArgs struct {
+	// File is the path to the entry .jsonnet file, resolved through the
+	// same Loader used for the rest of the kustomization.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// ExtVars sets Jsonnet external variables (std.extVar) as string
+	// literals.
+	ExtVars map[string]string `json:"extVars,omitempty" yaml:"extVars,omitempty"`
+
+	// ExtCode sets Jsonnet external variables whose values are Jsonnet
+	// code rather than string literals.
+	ExtCode map[string]string `json:"extCode,omitempty" yaml:"extCode,omitempty"`
+
+	// TLAs sets top-level argument values passed to the entry file's
+	// top-level function, if it has one.
+	TLAs map[string]string `json:"tlas,omitempty" yaml:"tlas,omitempty"`
+
+	// JPath lists additional library search paths consulted, after the
+	// entry file's own directory, when resolving import/importstr.
+	JPath []string `json:"jpath,omitempty" yaml:"jpath,omitempty"`
+
+	// AllowNativeFuncs enables the fixed allowlist of native functions
+	// (parseYaml, parseJson, regexMatch) available to the entry file.
+	AllowNativeFuncs bool `json:"allowNativeFuncs,omitempty" yaml:"allowNativeFuncs,omitempty"`
+}
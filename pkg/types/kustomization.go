@@ -0,0 +1,30 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// Kustomization is the subset of a kustomization.yaml's generator
+// configuration whose list entries are arguments to resource.Factory's
+// Make* methods, one list entry per call.
+type Kustomization struct {
+	ConfigMapGenerator []ConfigMapArgs `json:"configMapGenerator,omitempty" yaml:"configMapGenerator,omitempty"`
+	SecretGenerator    []SecretArgs    `json:"secretGenerator,omitempty" yaml:"secretGenerator,omitempty"`
+
+	// JsonnetGenerator lists Jsonnet entry files for
+	// resource.Factory.MakeJsonnet (see Factory.MakeJsonnetResources),
+	// alongside ConfigMapGenerator and SecretGenerator.
+	JsonnetGenerator []JsonnetArgs `json:"jsonnetGenerator,omitempty" yaml:"jsonnetGenerator,omitempty"`
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// SecretArgs contains the metadata of how to generate a secret.
+type SecretArgs struct {
+	// GeneratorArgs for the secret.
+	GeneratorArgs
+
+	// Type of the secret.
+	//
+	// This is the value of the "Type" field of the Kubernetes Secret,
+	// defaults to "Opaque".
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Encryption, when set, has the Factory encrypt every data value
+	// through the named SecretProvider before the Secret (or, for the
+	// "sealed-secrets" provider, SealedSecret) is emitted.
+	Encryption *EncryptionArgs `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+}